@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/DrakeW/corgi/completion"
+	"github.com/DrakeW/corgi/config"
+	"github.com/spf13/cobra"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate or manage corgi's shell completion script",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shell := "bash"
+		if len(args) == 1 {
+			shell = args[0]
+		}
+		switch shell {
+		case "bash":
+			return rootCmd.GenBashCompletion(os.Stdout)
+		case "zsh":
+			return rootCmd.GenZshCompletion(os.Stdout)
+		case "fish":
+			return rootCmd.GenFishCompletion(os.Stdout, true)
+		case "powershell":
+			return rootCmd.GenPowerShellCompletion(os.Stdout)
+		default:
+			return fmt.Errorf("unsupported shell: %s", shell)
+		}
+	},
+}
+
+var completionInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install corgi completion into your shell's rc file",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(configFileFlag)
+		if err != nil {
+			return err
+		}
+		shells := completion.DetectShells()
+		if len(shells) == 0 {
+			return fmt.Errorf("could not detect a shell to install completion for, set $SHELL")
+		}
+
+		if cfg.CompletionState == nil {
+			cfg.CompletionState = &completion.State{InstalledAt: map[string]string{}}
+		}
+		for _, shell := range shells {
+			rcFile, err := completion.Install(shell)
+			if err != nil {
+				return fmt.Errorf("could not install completion for %s: %v", shell, err)
+			}
+			cfg.CompletionState.InstalledAt[shell] = rcFile
+			found := false
+			for _, s := range cfg.CompletionState.Shells {
+				if s == shell {
+					found = true
+					break
+				}
+			}
+			if !found {
+				cfg.CompletionState.Shells = append(cfg.CompletionState.Shells, shell)
+			}
+			fmt.Printf("installed completion for %s into %s\n", shell, rcFile)
+		}
+		return cfg.Save()
+	},
+}
+
+var completionUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove corgi completion from your shell's rc file(s)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(configFileFlag)
+		if err != nil {
+			return err
+		}
+		if cfg.CompletionState == nil {
+			return nil
+		}
+		for shell, rcFile := range cfg.CompletionState.InstalledAt {
+			if err := completion.Uninstall(rcFile); err != nil {
+				return fmt.Errorf("could not uninstall completion for %s: %v", shell, err)
+			}
+			fmt.Printf("removed completion for %s from %s\n", shell, rcFile)
+		}
+		cfg.CompletionState = nil
+		return cfg.Save()
+	},
+}
+
+func init() {
+	completionCmd.AddCommand(completionInstallCmd, completionUninstallCmd)
+	rootCmd.AddCommand(completionCmd)
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DrakeW/corgi/config"
+	"github.com/DrakeW/corgi/filter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configureEditorFlag     string
+	configureFilterFlag     string
+	configureFilterArgsFlag string
+	configureMigrateFlag    string
+)
+
+var configureCmd = &cobra.Command{
+	Use:   "configure",
+	Short: "View or update corgi's configuration",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(configFileFlag)
+		if err != nil {
+			return err
+		}
+		if configureEditorFlag != "" {
+			cfg.Editor = configureEditorFlag
+		}
+		if configureFilterFlag != "" {
+			f, err := filter.ByName(configureFilterFlag)
+			if err != nil {
+				return fmt.Errorf("could not configure filter %q: %v", configureFilterFlag, err)
+			}
+			if configureFilterArgsFlag != "" {
+				f.Args = strings.Fields(configureFilterArgsFlag)
+			}
+			cfg.Filter = f
+			cfg.FilterCmd = f.Path
+		}
+		if configureMigrateFlag != "" {
+			return cfg.MigrateTo(configureMigrateFlag)
+		}
+		return cfg.Save()
+	},
+}
+
+func init() {
+	configureCmd.Flags().StringVar(&configureEditorFlag, "editor", "", "path to the editor used to add/edit snippets")
+	configureCmd.Flags().StringVar(&configureFilterFlag, "filter", "", "filter backend to use (fzf, peco, sk, gum)")
+	configureCmd.Flags().StringVar(&configureFilterArgsFlag, "filter-args", "", "space-separated args passed to the filter backend")
+	configureCmd.Flags().StringVar(&configureMigrateFlag, "migrate", "", "convert the existing config file to a different format (json, toml, yaml)")
+	rootCmd.AddCommand(configureCmd)
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var configFileFlag string
+
+var rootCmd = &cobra.Command{
+	Use:   "corgi",
+	Short: "corgi is a command-line snippet manager",
+}
+
+// Execute runs the root command, exiting the process on error.
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configFileFlag, "config", "", "path to a corgi config file (overrides $CORGI_CONFIG and the default search locations)")
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DrakeW/corgi/config"
+	"github.com/DrakeW/corgi/sync"
+	"github.com/spf13/cobra"
+)
+
+var syncDryRunFlag bool
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Pull and push snippets to the configured remote",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(configFileFlag)
+		if err != nil {
+			return err
+		}
+		if cfg.Sync == nil {
+			return errors.New("no sync backend configured, set Config.Sync in your config file")
+		}
+		files, err := cfg.SnippetFiles()
+		if err != nil {
+			return err
+		}
+		if syncDryRunFlag {
+			fmt.Printf("would pull from and push to %s (%s, branch %s): %v\n", cfg.Sync.URL, cfg.Sync.Backend, cfg.Sync.Branch, files)
+			return nil
+		}
+		if err := sync.Pull(cfg.Sync, files); err != nil {
+			return err
+		}
+		return sync.Push(cfg.Sync, files)
+	},
+}
+
+func init() {
+	syncCmd.Flags().BoolVar(&syncDryRunFlag, "dry-run", false, "print what would be synced without touching the remote")
+	rootCmd.AddCommand(syncCmd)
+}
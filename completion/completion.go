@@ -0,0 +1,154 @@
+// Package completion installs and removes shell completion source-lines
+// from a user's rc files, tracking what it wrote so the process can be
+// cleanly reversed later, even after the corgi binary has moved.
+package completion
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+)
+
+// State records which shells corgi has installed completion for, and where,
+// so "corgi completion uninstall" can find and remove it again. It is
+// persisted via config.Config.Save().
+type State struct {
+	Shells      []string          `json:"shells,omitempty" toml:"shells,omitempty" yaml:"shells,omitempty"`
+	InstalledAt map[string]string `json:"installed_at,omitempty" toml:"installed_at,omitempty" yaml:"installed_at,omitempty"`
+}
+
+const (
+	sentinelStart = "# >>> corgi completion >>>"
+	sentinelEnd   = "# <<< corgi completion <<<"
+)
+
+var ErrUnsupportedShell = fmt.Errorf("completion: unsupported shell")
+
+// DetectShells returns the shell(s) corgi should install completion for,
+// based on $SHELL and the presence of a fish config.fish file.
+func DetectShells() []string {
+	var shells []string
+	if sh := os.Getenv("SHELL"); sh != "" {
+		shells = append(shells, path.Base(sh))
+	}
+	if _, err := os.Stat(fishConfigFile()); err == nil {
+		shells = append(shells, "fish")
+	}
+	return dedupe(shells)
+}
+
+func dedupe(in []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, s := range in {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func configHome() string {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return xdg
+	}
+	return path.Join(os.Getenv("HOME"), ".config")
+}
+
+func fishConfigFile() string {
+	return path.Join(configHome(), "fish", "config.fish")
+}
+
+// RCFile returns the rc file corgi would source a completion script from
+// for the given shell.
+func RCFile(shell string) (string, error) {
+	home := os.Getenv("HOME")
+	switch shell {
+	case "bash":
+		return path.Join(home, ".bashrc"), nil
+	case "zsh":
+		return path.Join(home, ".zshrc"), nil
+	case "fish":
+		return fishConfigFile(), nil
+	case "pwsh", "powershell":
+		return path.Join(configHome(), "powershell", "Microsoft.PowerShell_profile.ps1"), nil
+	default:
+		return "", ErrUnsupportedShell
+	}
+}
+
+// sourceLine returns the line that makes shell source binary's completion
+// script.
+func sourceLine(binary, shell string) string {
+	switch shell {
+	case "fish":
+		return fmt.Sprintf("%s completion fish | source", binary)
+	case "pwsh", "powershell":
+		return fmt.Sprintf("%s completion powershell | Out-String | Invoke-Expression", binary)
+	default: // bash, zsh
+		return fmt.Sprintf("source <(%s completion %s)", binary, shell)
+	}
+}
+
+// Install idempotently appends a source-line for shell's completion to its
+// rc file, using the current binary's path, and returns that rc file.
+// Calling Install again for a shell that's already set up is a no-op.
+func Install(shell string) (string, error) {
+	binary, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	rcFile, err := RCFile(shell)
+	if err != nil {
+		return "", err
+	}
+
+	existing, err := ioutil.ReadFile(rcFile)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if strings.Contains(string(existing), sentinelStart) {
+		return rcFile, nil
+	}
+
+	block := fmt.Sprintf("\n%s\n%s\n%s\n", sentinelStart, sourceLine(binary, shell), sentinelEnd)
+	f, err := os.OpenFile(rcFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(block); err != nil {
+		return "", err
+	}
+	return rcFile, nil
+}
+
+// Uninstall removes the sentinel-delimited block Install wrote to rcFile. It
+// is a no-op if the block isn't present (e.g. the user already removed it).
+func Uninstall(rcFile string) error {
+	data, err := ioutil.ReadFile(rcFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	lines := strings.Split(string(data), "\n")
+	var out []string
+	inBlock := false
+	for _, line := range lines {
+		switch {
+		case strings.TrimSpace(line) == sentinelStart:
+			inBlock = true
+		case strings.TrimSpace(line) == sentinelEnd:
+			inBlock = false
+		case !inBlock:
+			out = append(out, line)
+		}
+	}
+	return ioutil.WriteFile(rcFile, []byte(strings.Join(out, "\n")), 0644)
+}
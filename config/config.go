@@ -2,26 +2,65 @@ package config
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path"
+	"path/filepath"
 	"runtime"
+	"strings"
 
-	"github.com/DrakeW/corgi/util"
+	"github.com/DrakeW/corgi/completion"
+	"github.com/DrakeW/corgi/configcodec"
+	"github.com/DrakeW/corgi/filter"
+	"github.com/DrakeW/corgi/sync"
 )
 
 type Config struct {
-	SnippetsFile string `json:"snippets_file"`
-	SnippetsDir  string `json:"snippets_dir"`
-	Editor       string `json:"editor"`
-	FilterCmd    string `json:"filter_cmd"`
+	SnippetsFile    string            `json:"snippets_file" toml:"snippets_file" yaml:"snippets_file"`
+	SnippetsDirs    []string          `json:"snippets_dirs" toml:"snippets_dirs" yaml:"snippets_dirs"`
+	Editor          string            `json:"editor" toml:"editor" yaml:"editor"`
+	FilterCmd       string            `json:"filter_cmd,omitempty" toml:"filter_cmd,omitempty" yaml:"filter_cmd,omitempty"`
+	Filter          *filter.Filter    `json:"filter" toml:"filter" yaml:"filter"`
+	Sync            *sync.Config      `json:"sync,omitempty" toml:"sync,omitempty" yaml:"sync,omitempty"`
+	CompletionState *completion.State `json:"completion,omitempty" toml:"completion,omitempty" yaml:"completion,omitempty"`
+
+	// sourceFile is the config file Load() actually merged this config's
+	// highest-priority values from (or, if none existed, the empty file Load()
+	// created at the highest-priority location). It is
+	// not serialized; use SourceFile() to read it.
+	sourceFile string
+}
+
+// UnmarshalJSON migrates legacy single-value config keys into their
+// replacements: "snippets_dir" into SnippetsDirs, and "filter_cmd" (a bare
+// binary path) into Filter.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type configAlias Config
+	aux := &struct {
+		SnippetsDir string `json:"snippets_dir"`
+		*configAlias
+	}{
+		configAlias: (*configAlias)(c),
+	}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	if aux.SnippetsDir != "" && len(c.SnippetsDirs) == 0 {
+		c.SnippetsDirs = []string{aux.SnippetsDir}
+	}
+	if c.Filter == nil && c.FilterCmd != "" {
+		c.Filter = &filter.Filter{Name: path.Base(c.FilterCmd), Path: c.FilterCmd}
+	}
+	return nil
 }
 
 const (
-	DEFAULT_CONFIG_FILE     = ".corgi/corgi_conf.json"
+	DEFAULT_CONFIG_FILE     = ".corgi/corgi_conf.json" // legacy, $HOME-relative
+	XDG_CONFIG_FILE         = "corgi/config.json"      // relative to $XDG_CONFIG_HOME or $HOME/.config
+	SYSTEM_CONFIG_FILE      = "/etc/corgi/config.json"
+	CONFIG_ENV_VAR          = "CORGI_CONFIG"
 	DEFAULT_SNIPPETS_DIR    = ".corgi/snippets"
 	DEFAULT_SNIPPETS_FILE   = ".corgi/snippets.json"
 	DEFAULT_EDITOR          = "vim"
@@ -29,7 +68,9 @@ const (
 	DEFAULT_FILTER_CMD_PECO = "peco"
 )
 
-var MissingDefaultFilterCmdError = errors.New("missing default filter cmd")
+// MissingDefaultFilterCmdError is returned when none of the known filter
+// backends (fzf, peco, sk, gum) can be found in $PATH.
+var MissingDefaultFilterCmdError = filter.ErrNoFilterCmd
 
 func getOrCreatePath(loc string, perm os.FileMode, isDir bool) error {
 	dirPath := path.Dir(loc)
@@ -51,21 +92,72 @@ func getOrCreatePath(loc string, perm os.FileMode, isDir bool) error {
 	return nil
 }
 
+// GetDefaultConfigHome returns the directory corgi derives its legacy
+// $HOME-relative paths (snippets, editor defaults, etc.) from. Config file
+// discovery itself is handled by ConfigSearchPaths, which also covers the
+// XDG and system-wide locations GetDefaultConfigHome doesn't know about.
 func GetDefaultConfigHome() string {
-	var configHome string
-	if runtime.GOOS == "darwin" {
-		configHome = os.Getenv("HOME")
-	} else if runtime.GOOS == "linux" {
-		configHome = os.Getenv("XDG_CONFIG_HOME")
-		if configHome == "" {
-			configHome = os.Getenv("HOME")
+	if runtime.GOOS == "windows" {
+		return os.Getenv("APPDATA")
+	}
+	return os.Getenv("HOME")
+}
+
+// ConfigSearchPaths returns every location corgi looks for a config file, in
+// descending priority:
+//  1. explicitPath, e.g. from the --config flag or $CORGI_CONFIG
+//  2. $XDG_CONFIG_HOME/corgi/config.json (macOS: ~/Library/Application Support/corgi/config.json, Windows: %APPDATA%\corgi\config.json)
+//  3. $HOME/.config/corgi/config.json
+//  4. $HOME/.corgi/corgi_conf.json (legacy)
+//  5. /etc/corgi/config.json (system-wide)
+//
+// Load merges whichever of these exist, with higher-priority entries
+// overriding lower ones.
+func ConfigSearchPaths(explicitPath string) []string {
+	var paths []string
+	if explicitPath != "" {
+		paths = append(paths, explicitPath)
+	}
+	if env := os.Getenv(CONFIG_ENV_VAR); env != "" {
+		paths = append(paths, env)
+	}
+
+	home := os.Getenv("HOME")
+	switch {
+	case runtime.GOOS == "windows":
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			paths = append(paths, path.Join(appData, XDG_CONFIG_FILE))
+		}
+	case runtime.GOOS == "darwin":
+		if home != "" {
+			paths = append(paths, path.Join(home, "Library", "Application Support", XDG_CONFIG_FILE))
 		}
+	default:
+		if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+			paths = append(paths, path.Join(xdg, XDG_CONFIG_FILE))
+		}
+	}
+
+	if home != "" {
+		paths = append(paths, path.Join(home, ".config", XDG_CONFIG_FILE))
+		paths = append(paths, path.Join(home, DEFAULT_CONFIG_FILE))
 	}
-	return configHome
+	paths = append(paths, SYSTEM_CONFIG_FILE)
+	return paths
 }
 
-func GetDefaultConfigFile(configHome string) (string, error) {
-	var defaultConfigFileLoc = path.Join(configHome, DEFAULT_CONFIG_FILE)
+// GetDefaultConfigFile returns the highest-priority config file location
+// that either already exists or can be created, creating it (along with any
+// parent directories) if necessary.
+func GetDefaultConfigFile(explicitPath string) (string, error) {
+	paths := ConfigSearchPaths(explicitPath)
+	for _, p := range paths {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	// nothing exists yet; create the highest-priority candidate
+	defaultConfigFileLoc := paths[0]
 	if err := getOrCreatePath(defaultConfigFileLoc, 0755, false); err != nil {
 		return "", err
 	}
@@ -100,33 +192,37 @@ func GetDefaultEditor() (string, error) {
 	return editorPath, nil
 }
 
-func GetDefaultFilterCmd() (string, error) {
-	filterCmdPath, err := exec.LookPath(DEFAULT_FILTER_CMD_PECO)
-	if err != nil {
-		filterCmdPath = ""
-	}
-	filterCmdPath, err = exec.LookPath(DEFAULT_FILTER_CMD_FZF)
-	if err != nil {
-		filterCmdPath = ""
-	}
-	if filterCmdPath == "" {
-		return "", MissingDefaultFilterCmdError
-	}
-	return filterCmdPath, nil
-}
-
-func Load() (*Config, error) {
-	// find config dir location
+// Load builds a Config by merging every config file ConfigSearchPaths(explicitPath)
+// finds, from lowest to highest priority, so an explicit --config/$CORGI_CONFIG
+// path wins over the XDG location, which wins over the legacy one, and so on.
+func Load(explicitPath string) (*Config, error) {
 	configHome := GetDefaultConfigHome()
-	// loading other config files
-	configFile, err := GetDefaultConfigFile(configHome)
-	if err != nil {
-		return nil, err
-	}
+	paths := ConfigSearchPaths(explicitPath)
 	config := &Config{}
-	if err = util.LoadJsonDataFromFile(configFile, config); err != nil {
-		return nil, err
+	var sourceFile string
+	for i := len(paths) - 1; i >= 0; i-- {
+		p := paths[i]
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		if strings.TrimSpace(string(data)) != "" {
+			if err := configcodec.ForPath(p).Unmarshal(data, config); err != nil {
+				return nil, err
+			}
+		}
+		sourceFile = p
+	}
+	if sourceFile == "" {
+		// none of the layered locations exist yet; fall back to creating the
+		// highest-priority candidate so later saves have somewhere to land
+		configFile, err := GetDefaultConfigFile(explicitPath)
+		if err != nil {
+			return nil, err
+		}
+		sourceFile = configFile
 	}
+	config.sourceFile = sourceFile
 	// if config file has no content, initialize it with default
 	if config.IsNew() {
 		// set default snippets file
@@ -140,7 +236,7 @@ func Load() (*Config, error) {
 		if err != nil {
 			return nil, err
 		}
-		config.SnippetsDir = snippetsDir
+		config.SnippetsDirs = []string{snippetsDir}
 		// set default editor
 		editor, err := GetDefaultEditor()
 		if err != nil {
@@ -148,32 +244,134 @@ func Load() (*Config, error) {
 		}
 		config.Editor = editor
 		// set default filter cmd
-		filterCmd, err := GetDefaultFilterCmd()
+		f, err := filter.GetDefaultFilterCmd()
 		if err != nil && err != MissingDefaultFilterCmdError {
 			return nil, err
 		}
-		config.FilterCmd = filterCmd
+		config.Filter = f
+		if f != nil {
+			config.FilterCmd = f.Path
+		}
 		// save
 		config.Save()
 	}
+	if config.Sync != nil && config.Sync.AutoSync {
+		files, err := config.SnippetFiles()
+		if err != nil && err != sync.ErrNothingToSync {
+			return nil, err
+		}
+		if err == nil {
+			if err := sync.Pull(config.Sync, files); err != nil {
+				return nil, err
+			}
+		}
+	}
 	return config, nil
 }
 
+// Save writes the config back to the file it was loaded from (see
+// SourceFile), creating it first if Load never found one.
 func (c *Config) Save() error {
-	configHome := GetDefaultConfigHome()
-	// get config file
-	confFile, err := GetDefaultConfigFile(configHome)
+	confFile := c.sourceFile
+	if confFile == "" {
+		var err error
+		confFile, err = GetDefaultConfigFile("")
+		if err != nil {
+			return err
+		}
+		c.sourceFile = confFile
+	}
+	data, err := configcodec.ForPath(confFile).Marshal(c)
 	if err != nil {
 		return err
 	}
-	data, err := json.MarshalIndent(c, util.JSON_MARSHAL_PREFIX, util.JSON_MARSHAL_INDENT)
-	if err != nil {
+	if err := ioutil.WriteFile(confFile, data, 0644); err != nil {
 		return err
 	}
-	err = ioutil.WriteFile(confFile, data, 0644)
-	return err
+	if c.Sync != nil && c.Sync.AutoSync {
+		files, err := c.SnippetFiles()
+		if err != nil && err != sync.ErrNothingToSync {
+			return err
+		}
+		if err == nil {
+			return sync.Push(c.Sync, files)
+		}
+	}
+	return nil
+}
+
+// MigrateTo rewrites the config to use the given format (e.g. "toml",
+// "yaml", "json"), renaming its source file's extension accordingly and
+// removing the old file once the new one has been saved.
+func (c *Config) MigrateTo(ext string) error {
+	codec, ok := configcodec.ForExt(ext)
+	if !ok {
+		return fmt.Errorf("unsupported config format %q", ext)
+	}
+	oldFile := c.sourceFile
+	newFile := strings.TrimSuffix(oldFile, path.Ext(oldFile)) + "." + codec.Ext()
+	c.sourceFile = newFile
+	if err := c.Save(); err != nil {
+		return err
+	}
+	if newFile != oldFile {
+		if err := os.Remove(oldFile); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// SourceFile returns the config file this Config was loaded from, or will be
+// written to on the next Save() if it didn't exist at load time.
+func (c *Config) SourceFile() string {
+	return c.sourceFile
 }
 
 func (c *Config) IsNew() bool {
-	return c.SnippetsFile == "" && c.SnippetsDir == "" && c.Editor == "" && c.FilterCmd == ""
+	return c.SnippetsFile == "" && len(c.SnippetsDirs) == 0 && c.Editor == "" && c.FilterCmd == "" && c.Filter == nil
+}
+
+// AddSnippetsDir appends dir to the list of configured snippet directories if
+// it isn't already present.
+func (c *Config) AddSnippetsDir(dir string) {
+	for _, d := range c.SnippetsDirs {
+		if d == dir {
+			return
+		}
+	}
+	c.SnippetsDirs = append(c.SnippetsDirs, dir)
+}
+
+// SnippetFiles returns every JSON snippet file sync should cover: c.SnippetsFile
+// (if set) plus every *.json file found by recursively walking c.SnippetsDirs.
+// It returns sync.ErrNothingToSync if that finds no file at all (e.g. neither
+// is configured, or the directories hold no *.json file), so callers get a
+// clear error instead of syncing a file literally named "" or ".".
+func (c *Config) SnippetFiles() ([]string, error) {
+	var files []string
+	if c.SnippetsFile != "" {
+		files = append(files, c.SnippetsFile)
+	}
+	for _, dir := range c.SnippetsDirs {
+		if dir == "" {
+			continue
+		}
+		err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && filepath.Ext(p) == ".json" {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if len(files) == 0 {
+		return nil, sync.ErrNothingToSync
+	}
+	return files, nil
 }
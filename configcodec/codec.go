@@ -0,0 +1,42 @@
+// Package configcodec dispatches config and snippet (de)serialization to a
+// format-specific codec based on file extension, so corgi can read and
+// write JSON, TOML or YAML interchangeably. Snippet files are top-level
+// arrays, which TOML cannot represent, so only config files work in all
+// three formats; snippet files work in JSON and YAML.
+package configcodec
+
+import (
+	"path"
+	"strings"
+)
+
+// Codec marshals and unmarshals corgi's config/snippet files in a
+// particular format.
+type Codec interface {
+	// Ext is the file extension (without leading dot) this codec handles.
+	Ext() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var registry = map[string]Codec{}
+
+// Register adds c to the set of codecs dispatched on by its Ext().
+func Register(c Codec) {
+	registry[c.Ext()] = c
+}
+
+// ForExt returns the codec registered for ext (with or without a leading dot).
+func ForExt(ext string) (Codec, bool) {
+	c, ok := registry[strings.TrimPrefix(ext, ".")]
+	return c, ok
+}
+
+// ForPath returns the codec registered for loc's file extension, falling
+// back to the JSON codec when the extension is missing or unrecognized.
+func ForPath(loc string) Codec {
+	if c, ok := ForExt(path.Ext(loc)); ok {
+		return c
+	}
+	return registry["json"]
+}
@@ -0,0 +1,19 @@
+package configcodec
+
+import (
+	"encoding/json"
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Ext() string { return "json" }
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.MarshalIndent(v, "", "  ")
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func init() { Register(jsonCodec{}) }
@@ -0,0 +1,26 @@
+package configcodec
+
+import (
+	"bytes"
+
+	"github.com/BurntSushi/toml"
+)
+
+type tomlCodec struct{}
+
+func (tomlCodec) Ext() string { return "toml" }
+
+func (tomlCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (tomlCodec) Unmarshal(data []byte, v interface{}) error {
+	_, err := toml.Decode(string(data), v)
+	return err
+}
+
+func init() { Register(tomlCodec{}) }
@@ -0,0 +1,17 @@
+package configcodec
+
+import "gopkg.in/yaml.v2"
+
+type yamlCodec struct{}
+
+func (yamlCodec) Ext() string { return "yaml" }
+
+func (yamlCodec) Marshal(v interface{}) ([]byte, error) {
+	return yaml.Marshal(v)
+}
+
+func (yamlCodec) Unmarshal(data []byte, v interface{}) error {
+	return yaml.Unmarshal(data, v)
+}
+
+func init() { Register(yamlCodec{}) }
@@ -0,0 +1,107 @@
+package filter
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"text/template"
+)
+
+// Layout names where the filter backend renders its selection UI. It is
+// passed to arg templates as {{.Layout}} and has no effect unless one of a
+// Filter's Args references it.
+type Layout string
+
+const (
+	LayoutTopDown  Layout = "top-down"
+	LayoutBottomUp Layout = "bottom-up"
+)
+
+// Filter describes a selector backend (fzf, peco, sk, gum, ...) and the
+// arguments corgi invokes it with. Args are text/template strings rendered
+// against a TemplateData before the backend is exec'd, so users can pass
+// real flags (e.g. "--height 40%") without corgi needing to understand them.
+type Filter struct {
+	Name   string   `json:"name" toml:"name" yaml:"name"`
+	Path   string   `json:"path" toml:"path" yaml:"path"`
+	Args   []string `json:"args,omitempty" toml:"args,omitempty" yaml:"args,omitempty"`
+	Layout Layout   `json:"layout,omitempty" toml:"layout,omitempty" yaml:"layout,omitempty"`
+}
+
+// TemplateData is the data available to each arg's template.
+type TemplateData struct {
+	Prompt string
+	Layout Layout
+}
+
+type knownBackend struct {
+	name   string
+	args   []string
+	layout Layout
+}
+
+// backends lists well-known filter binaries, in the order GetDefaultFilterCmd
+// probes $PATH for them.
+var backends = []knownBackend{
+	{name: "fzf", args: []string{"--prompt", "{{.Prompt}}"}, layout: LayoutBottomUp},
+	{name: "peco", args: []string{"--prompt", "{{.Prompt}}"}, layout: LayoutTopDown},
+	{name: "sk", args: []string{"--prompt", "{{.Prompt}}"}, layout: LayoutBottomUp},
+	{name: "gum", args: []string{"filter", "--placeholder", "{{.Prompt}}"}, layout: LayoutTopDown},
+}
+
+var ErrNoFilterCmd = errors.New("no known filter command (fzf, peco, sk, gum) found in $PATH")
+
+// GetDefaultFilterCmd probes the known backends in preference order and
+// returns the first one found in $PATH.
+func GetDefaultFilterCmd() (*Filter, error) {
+	for _, b := range backends {
+		if p, err := exec.LookPath(b.name); err == nil {
+			return &Filter{Name: b.name, Path: p, Args: b.args, Layout: b.layout}, nil
+		}
+	}
+	return nil, ErrNoFilterCmd
+}
+
+// ByName looks up a well-known backend by name and resolves its path via
+// $PATH, without probing the other backends.
+func ByName(name string) (*Filter, error) {
+	for _, b := range backends {
+		if b.name != name {
+			continue
+		}
+		p, err := exec.LookPath(name)
+		if err != nil {
+			return nil, err
+		}
+		return &Filter{Name: b.name, Path: p, Args: b.args, Layout: b.layout}, nil
+	}
+	return nil, errors.New("unknown filter backend: " + name)
+}
+
+// Render expands f.Args as text/templates against data, e.g. substituting
+// {{.Prompt}} and {{.Layout}}.
+func (f *Filter) Render(data TemplateData) ([]string, error) {
+	rendered := make([]string, len(f.Args))
+	for i, a := range f.Args {
+		tmpl, err := template.New("arg").Parse(a)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		rendered[i] = buf.String()
+	}
+	return rendered, nil
+}
+
+// Command builds the *exec.Cmd that runs f with its args templated against
+// data, ready for the snippet runner to wire up stdin/stdout and Run().
+func (f *Filter) Command(data TemplateData) (*exec.Cmd, error) {
+	args, err := f.Render(data)
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(f.Path, args...), nil
+}
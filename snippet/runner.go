@@ -0,0 +1,49 @@
+package snippet
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/DrakeW/corgi/config"
+	"github.com/DrakeW/corgi/filter"
+)
+
+var ErrNoFilterConfigured = errors.New("no filter backend configured, run \"corgi configure --filter <name>\"")
+
+// Pick runs the configured filter backend over snippets and returns the one
+// the user selected. Each snippet is rendered as "<description>\t<command>"
+// so the backend can match against either half; the chosen line is mapped
+// back to its Snippet by index.
+func Pick(cfg *config.Config, snippets []Snippet, prompt string) (*Snippet, error) {
+	if cfg.Filter == nil {
+		return nil, ErrNoFilterConfigured
+	}
+
+	lines := make([]string, len(snippets))
+	for i, s := range snippets {
+		lines[i] = s.Description + "\t" + s.Command
+	}
+
+	cmd, err := cfg.Filter.Command(filter.TemplateData{Prompt: prompt, Layout: cfg.Filter.Layout})
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdin = strings.NewReader(strings.Join(lines, "\n"))
+	cmd.Stderr = os.Stderr
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+
+	selected := strings.TrimRight(out.String(), "\n")
+	for i, line := range lines {
+		if line == selected {
+			return &snippets[i], nil
+		}
+	}
+	return nil, nil
+}
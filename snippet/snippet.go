@@ -0,0 +1,124 @@
+package snippet
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/DrakeW/corgi/config"
+	"github.com/DrakeW/corgi/configcodec"
+)
+
+// Snippet is a single saved command, optionally tagged for easier lookup. ID
+// is assigned once by Add and kept stable across edits, so sync can tell an
+// edited snippet apart from a newly added one with similar content.
+type Snippet struct {
+	ID          string   `json:"id,omitempty" toml:"id,omitempty" yaml:"id,omitempty"`
+	Command     string   `json:"command" toml:"command" yaml:"command"`
+	Description string   `json:"description" toml:"description" yaml:"description"`
+	Tags        []string `json:"tags,omitempty" toml:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+var ErrNoSnippetsDestination = errors.New("no snippets file or directory configured")
+
+// LoadAll reads cfg.SnippetsFile (the destination Add() writes to by
+// default) and recursively walks every directory configured in
+// cfg.SnippetsDirs, reading every snippet file it finds there with an
+// extension configcodec knows about (.json, .toml or .yaml), and merges them
+// all into a single working set. This lets a personal snippet tree and a
+// shared/team tree checked into git be mixed together transparently.
+// A snippet file is a top-level array, which the TOML codec cannot decode,
+// so a .toml file in a snippets directory makes LoadAll return an error;
+// snippet files work in JSON and YAML only.
+func LoadAll(cfg *config.Config) ([]Snippet, error) {
+	var all []Snippet
+	if cfg.SnippetsFile != "" {
+		snippets, err := loadFile(cfg.SnippetsFile)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to load snippets from %s: %v", cfg.SnippetsFile, err)
+		}
+		all = append(all, snippets...)
+	}
+	for _, dir := range cfg.SnippetsDirs {
+		if dir == "" {
+			continue
+		}
+		err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			if _, ok := configcodec.ForExt(filepath.Ext(p)); !ok {
+				return nil
+			}
+			snippets, err := loadFile(p)
+			if err != nil {
+				return fmt.Errorf("failed to load snippets from %s: %v", p, err)
+			}
+			all = append(all, snippets...)
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return all, nil
+}
+
+func loadFile(path string) ([]Snippet, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snippets []Snippet
+	if err := configcodec.ForPath(path).Unmarshal(data, &snippets); err != nil {
+		return nil, err
+	}
+	return snippets, nil
+}
+
+// Add saves a new snippet to cfg.SnippetsFile. When no snippets file is
+// configured, it falls back to a time-stamped file in the first configured
+// snippets directory so the new snippet still ends up somewhere discoverable
+// by LoadAll.
+func Add(cfg *config.Config, s Snippet) error {
+	dest := cfg.SnippetsFile
+	if dest == "" {
+		if len(cfg.SnippetsDirs) == 0 {
+			return ErrNoSnippetsDestination
+		}
+		dest = filepath.Join(cfg.SnippetsDirs[0], fmt.Sprintf("snippets-%d.json", time.Now().Unix()))
+	}
+
+	var snippets []Snippet
+	if existing, err := loadFile(dest); err == nil {
+		snippets = existing
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if s.ID == "" {
+		s.ID = newID()
+	}
+	snippets = append(snippets, s)
+
+	data, err := configcodec.ForPath(dest).Marshal(snippets)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(dest, data, 0644)
+}
@@ -0,0 +1,184 @@
+package sync
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// baseFile returns the sidecar path gitPull/gitPush use to remember the last
+// state both sides of the sync agreed on, so MergeJSONArrays can tell an
+// edit from an unrelated add/remove instead of always diffing against
+// nothing.
+func baseFile(localFile string) string {
+	dir, name := filepath.Split(localFile)
+	return filepath.Join(dir, "."+name+".sync-base")
+}
+
+func readIfExists(path string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil && os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// gitPull clones cfg.URL into a scratch worktree and three-way merges each of
+// localFiles' basename into the local file, using the last-synced snapshot
+// in baseFile(localFile) as the merge base.
+func gitPull(cfg *Config, localFiles []string) error {
+	worktree, err := checkout(cfg)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(worktree)
+
+	for _, localFile := range localFiles {
+		remoteFile := filepath.Join(worktree, filepath.Base(localFile))
+		remoteData, err := readIfExists(remoteFile)
+		if err != nil {
+			return err
+		}
+		if remoteData == nil {
+			continue // nothing to pull yet for this file
+		}
+
+		localData, err := readIfExists(localFile)
+		if err != nil {
+			return err
+		}
+		baseData, err := readIfExists(baseFile(localFile))
+		if err != nil {
+			return err
+		}
+
+		merged, err := MergeJSONArrays(baseData, localData, remoteData)
+		if err != nil {
+			return fmt.Errorf("sync: failed to merge %s: %v", localFile, err)
+		}
+		if err := ioutil.WriteFile(localFile, merged, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gitPush three-way merges each of localFiles into a scratch worktree's copy
+// of the repo (using the same base as gitPull), commits and pushes the
+// result back to cfg.URL, then records it as the new sync base for next time.
+func gitPush(cfg *Config, localFiles []string) error {
+	worktree, err := checkout(cfg)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(worktree)
+
+	for _, localFile := range localFiles {
+		remoteFile := filepath.Join(worktree, filepath.Base(localFile))
+		remoteData, err := readIfExists(remoteFile)
+		if err != nil {
+			return err
+		}
+		localData, err := readIfExists(localFile)
+		if err != nil {
+			return err
+		}
+		baseData, err := readIfExists(baseFile(localFile))
+		if err != nil {
+			return err
+		}
+
+		merged, err := MergeJSONArrays(baseData, localData, remoteData)
+		if err != nil {
+			return fmt.Errorf("sync: failed to merge %s: %v", localFile, err)
+		}
+		if err := ioutil.WriteFile(remoteFile, merged, 0644); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(localFile, merged, 0644); err != nil {
+			return err
+		}
+		// local and remote now agree on merged; it becomes the new merge base
+		if err := ioutil.WriteFile(baseFile(localFile), merged, 0644); err != nil {
+			return err
+		}
+	}
+
+	for _, args := range [][]string{
+		{"-C", worktree, "add", "."},
+		{"-C", worktree, "commit", "-m", "corgi sync"},
+		{"-C", worktree, "push", "origin", branch(cfg)},
+	} {
+		if out, err := gitCmd(cfg, args...).CombinedOutput(); err != nil {
+			if strings.Contains(string(out), "nothing to commit") {
+				continue
+			}
+			return fmt.Errorf("sync: %v: %s", err, out)
+		}
+	}
+	return nil
+}
+
+// checkout clones cfg's branch into a fresh temp directory.
+func checkout(cfg *Config) (string, error) {
+	worktree, err := ioutil.TempDir("", "corgi-sync")
+	if err != nil {
+		return "", err
+	}
+	args := []string{"clone", "--branch", branch(cfg), "--single-branch", cfg.URL, worktree}
+	if out, err := gitCmd(cfg, args...).CombinedOutput(); err != nil {
+		os.RemoveAll(worktree)
+		return "", fmt.Errorf("sync: git clone failed: %v: %s", err, out)
+	}
+	return worktree, nil
+}
+
+func branch(cfg *Config) string {
+	if cfg.Branch != "" {
+		return cfg.Branch
+	}
+	return "master"
+}
+
+// gitCmd builds a git invocation with SSH auth wired up per cfg.AuthMethod.
+// Token auth is expected to already be embedded in cfg.URL (e.g.
+// https://<token>@host/repo.git). Both ssh-key (KeyPath + optional
+// Passphrase) and ssh-pass (plain username + Passphrase-as-password) rely on
+// sshpass being installed, since git itself has no way to supply a secret
+// non-interactively. The secret is handed to sshpass via $SSHPASS, not an
+// argv flag, so it doesn't show up in `ps`/`/proc/<pid>/cmdline`.
+func gitCmd(cfg *Config, args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	env := os.Environ()
+	switch cfg.AuthMethod {
+	case AuthSSHKey:
+		if cfg.KeyPath == "" {
+			return cmd
+		}
+		sshCmd := fmt.Sprintf("ssh -i %s -o IdentitiesOnly=yes", shellQuote(cfg.KeyPath))
+		if cfg.Passphrase != "" {
+			env = append(env, "SSHPASS="+cfg.Passphrase)
+			sshCmd = "sshpass -P passphrase -e " + sshCmd
+		}
+		cmd.Env = append(env, "GIT_SSH_COMMAND="+sshCmd)
+	case AuthSSHPass:
+		sshCmd := "ssh -o PubkeyAuthentication=no -o PreferredAuthentications=password"
+		if cfg.Passphrase != "" {
+			env = append(env, "SSHPASS="+cfg.Passphrase)
+			sshCmd = "sshpass -e " + sshCmd
+		}
+		cmd.Env = append(env, "GIT_SSH_COMMAND="+sshCmd)
+	}
+	return cmd
+}
+
+// shellQuote wraps s in single quotes so it survives being embedded in the
+// GIT_SSH_COMMAND string git hands to /bin/sh -c, even if it contains spaces
+// or shell metacharacters (e.g. a macOS/Windows KeyPath like
+// "/Users/jane doe/.ssh/id_rsa").
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
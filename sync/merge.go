@@ -0,0 +1,109 @@
+package sync
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// record is a snippet loaded generically as a JSON object, so merge doesn't
+// need to depend on the snippet package's concrete type (and risk an import
+// cycle through config).
+type record map[string]interface{}
+
+// id returns the record's stable identity for merge purposes: its "id" field
+// when present (snippets created since identity tracking was added carry
+// one), or else a hash of command+description as a best-effort fallback for
+// older snippet files. The fallback can't distinguish "edited" from
+// "different snippet" since the hash changes with the content, so edits to
+// snippets without a real ID are merged as add/add rather than a tracked
+// conflict.
+func (r record) id() string {
+	if id, ok := r["id"].(string); ok && id != "" {
+		return id
+	}
+	h := sha1.New()
+	fmt.Fprintf(h, "%v\x00%v", r["command"], r["description"])
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// MergeJSONArrays three-way merges the JSON snippet arrays in base, local and
+// remote, keyed by each snippet's id() (see above). A snippet added on
+// either side since base is kept; one removed on one side but unchanged on
+// the other is dropped; one changed on both sides, or removed on one side
+// but edited on the other (an edit/delete conflict), prefers local, since
+// that's the copy the user is actively editing.
+func MergeJSONArrays(base, local, remote []byte) ([]byte, error) {
+	baseRecs, err := decodeRecords(base)
+	if err != nil {
+		return nil, err
+	}
+	localRecs, err := decodeRecords(local)
+	if err != nil {
+		return nil, err
+	}
+	remoteRecs, err := decodeRecords(remote)
+	if err != nil {
+		return nil, err
+	}
+
+	baseByID := indexByID(baseRecs)
+	localByID := indexByID(localRecs)
+	remoteByID := indexByID(remoteRecs)
+
+	merged := map[string]record{}
+	for id, r := range localByID {
+		merged[id] = r
+	}
+	for id, r := range remoteByID {
+		if localRec, inLocal := localByID[id]; inLocal {
+			if baseRec, inBase := baseByID[id]; inBase && reflect.DeepEqual(localRec, baseRec) {
+				merged[id] = r // edited remotely only; take the remote edit
+			}
+			continue // local wins on conflicting edits
+		}
+		if _, inBase := baseByID[id]; inBase {
+			continue // removed locally since base; keep it removed
+		}
+		merged[id] = r // added remotely since base
+	}
+	for id, baseRec := range baseByID {
+		if _, inRemote := remoteByID[id]; inRemote {
+			continue
+		}
+		// removed remotely since base; but if local independently edited it,
+		// that's an edit/delete conflict, and local wins like any other
+		// conflicting edit rather than silently losing the edit.
+		if localRec, inLocal := localByID[id]; inLocal && !reflect.DeepEqual(localRec, baseRec) {
+			continue
+		}
+		delete(merged, id) // removed remotely since base, unchanged locally
+	}
+
+	out := make([]record, 0, len(merged))
+	for _, r := range merged {
+		out = append(out, r)
+	}
+	return json.MarshalIndent(out, "", "  ")
+}
+
+func decodeRecords(data []byte) ([]record, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var recs []record
+	if err := json.Unmarshal(data, &recs); err != nil {
+		return nil, err
+	}
+	return recs, nil
+}
+
+func indexByID(recs []record) map[string]record {
+	byID := make(map[string]record, len(recs))
+	for _, r := range recs {
+		byID[r.id()] = r
+	}
+	return byID
+}
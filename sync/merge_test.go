@@ -0,0 +1,223 @@
+package sync
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func mustMarshal(t *testing.T, recs []record) []byte {
+	t.Helper()
+	data, err := json.Marshal(recs)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	return data
+}
+
+func decodeOrFail(t *testing.T, data []byte) []record {
+	t.Helper()
+	recs, err := decodeRecords(data)
+	if err != nil {
+		t.Fatalf("decodeRecords: %v", err)
+	}
+	return recs
+}
+
+func recordsByID(t *testing.T, data []byte) map[string]record {
+	t.Helper()
+	return indexByID(decodeOrFail(t, data))
+}
+
+func TestMergeJSONArraysAdd(t *testing.T) {
+	base := mustMarshal(t, []record{{"id": "a", "command": "ls"}})
+	local := mustMarshal(t, []record{{"id": "a", "command": "ls"}, {"id": "b", "command": "pwd"}})
+	remote := mustMarshal(t, []record{{"id": "a", "command": "ls"}, {"id": "c", "command": "whoami"}})
+
+	merged, err := MergeJSONArrays(base, local, remote)
+	if err != nil {
+		t.Fatalf("MergeJSONArrays: %v", err)
+	}
+	byID := recordsByID(t, merged)
+	for _, id := range []string{"a", "b", "c"} {
+		if _, ok := byID[id]; !ok {
+			t.Errorf("expected merged result to contain id %q, got %v", id, byID)
+		}
+	}
+}
+
+func TestMergeJSONArraysDeletionDoesNotResurrect(t *testing.T) {
+	base := mustMarshal(t, []record{{"id": "a", "command": "ls"}, {"id": "b", "command": "pwd"}})
+	local := mustMarshal(t, []record{{"id": "a", "command": "ls"}}) // "b" deleted locally
+	remote := base                                                  // remote hasn't seen the deletion yet
+
+	merged, err := MergeJSONArrays(base, local, remote)
+	if err != nil {
+		t.Fatalf("MergeJSONArrays: %v", err)
+	}
+	byID := recordsByID(t, merged)
+	if _, ok := byID["b"]; ok {
+		t.Errorf("deleted snippet %q was resurrected by merge: %v", "b", byID)
+	}
+	if _, ok := byID["a"]; !ok {
+		t.Errorf("expected unchanged snippet %q to survive merge", "a")
+	}
+}
+
+func TestMergeJSONArraysRemoteDeleteOfLocalEditKeepsEdit(t *testing.T) {
+	base := mustMarshal(t, []record{{"id": "a", "command": "ls"}})
+	local := mustMarshal(t, []record{{"id": "a", "command": "ls -la"}}) // edited locally
+	remote := mustMarshal(t, []record{})                                // deleted remotely
+
+	merged, err := MergeJSONArrays(base, local, remote)
+	if err != nil {
+		t.Fatalf("MergeJSONArrays: %v", err)
+	}
+	byID := recordsByID(t, merged)
+	rec, ok := byID["a"]
+	if !ok {
+		t.Fatalf("expected local edit to survive a remote delete/local edit conflict, got %v", byID)
+	}
+	if got := rec["command"]; got != "ls -la" {
+		t.Errorf("expected local edit %q to win, got %q", "ls -la", got)
+	}
+}
+
+func TestMergeJSONArraysRemovedRemotelyStaysRemoved(t *testing.T) {
+	base := mustMarshal(t, []record{{"id": "a", "command": "ls"}, {"id": "b", "command": "pwd"}})
+	local := base                                                   // local hasn't touched "b"
+	remote := mustMarshal(t, []record{{"id": "a", "command": "ls"}}) // "b" deleted remotely
+
+	merged, err := MergeJSONArrays(base, local, remote)
+	if err != nil {
+		t.Fatalf("MergeJSONArrays: %v", err)
+	}
+	byID := recordsByID(t, merged)
+	if _, ok := byID["b"]; ok {
+		t.Errorf("remotely-deleted snippet %q reappeared after merge: %v", "b", byID)
+	}
+}
+
+func TestMergeJSONArraysEditConflictLocalWins(t *testing.T) {
+	base := mustMarshal(t, []record{{"id": "a", "command": "ls"}})
+	local := mustMarshal(t, []record{{"id": "a", "command": "ls -la"}})
+	remote := mustMarshal(t, []record{{"id": "a", "command": "ls -lh"}})
+
+	merged, err := MergeJSONArrays(base, local, remote)
+	if err != nil {
+		t.Fatalf("MergeJSONArrays: %v", err)
+	}
+	byID := recordsByID(t, merged)
+	if len(byID) != 1 {
+		t.Fatalf("expected edit conflict to merge to a single record, got %v", byID)
+	}
+	if got := byID["a"]["command"]; got != "ls -la" {
+		t.Errorf("expected local edit to win conflict, got command %q", got)
+	}
+}
+
+// requireGit skips the test if git isn't available, since the round-trip
+// test below shells out to it against a local bare repo fixture.
+func requireGit(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found in $PATH")
+	}
+}
+
+// newBareFixture creates a bare git repo at dir/remote.git with a single
+// commit on branch master containing an initial snippets.json, and returns
+// the bare repo's path for use as a sync.Config.URL.
+func newBareFixture(t *testing.T, initial []byte) string {
+	t.Helper()
+	dir := t.TempDir()
+	bare := filepath.Join(dir, "remote.git")
+	seed := filepath.Join(dir, "seed")
+
+	run := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	run(dir, "init", "--bare", bare)
+	run(dir, "clone", bare, seed)
+	run(seed, "checkout", "-b", "master")
+	if err := os.WriteFile(filepath.Join(seed, "snippets.json"), initial, 0644); err != nil {
+		t.Fatalf("write seed snippets.json: %v", err)
+	}
+	run(seed, "-c", "user.email=test@example.com", "-c", "user.name=test", "add", "-A")
+	run(seed, "-c", "user.email=test@example.com", "-c", "user.name=test", "commit", "-m", "init")
+	run(seed, "push", "origin", "master")
+	return bare
+}
+
+// TestGitPullPushRoundTrip exercises Pull then Push against a local bare git
+// repo fixture, checking that an initial pull leaves the local file intact
+// and that a snippet added locally shows up on the remote after a push, as
+// seen by pulling into a second local file.
+func TestGitPullPushRoundTrip(t *testing.T) {
+	requireGit(t)
+
+	// gitPush commits via plain `git commit`, which needs an identity; set
+	// one for this process so it works in environments without global git
+	// config (CI, fresh containers) without touching the user's git config.
+	t.Setenv("GIT_AUTHOR_NAME", "corgi-test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "corgi-test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "corgi-test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "corgi-test@example.com")
+
+	initial := mustMarshal(t, []record{{"id": "a", "command": "ls"}})
+	bare := newBareFixture(t, initial)
+
+	localDir := t.TempDir()
+	localFile := filepath.Join(localDir, "snippets.json")
+	if err := os.WriteFile(localFile, initial, 0644); err != nil {
+		t.Fatalf("write local snippets.json: %v", err)
+	}
+
+	cfg := &Config{Backend: BackendGit, URL: bare, Branch: "master"}
+
+	if err := Pull(cfg, []string{localFile}); err != nil {
+		t.Fatalf("Pull: %v", err)
+	}
+	data, err := os.ReadFile(localFile)
+	if err != nil {
+		t.Fatalf("read localFile after pull: %v", err)
+	}
+	if byID := recordsByID(t, data); len(byID) != 1 {
+		t.Fatalf("expected 1 snippet after initial pull, got %v", byID)
+	}
+
+	// add a snippet locally, then push it to the remote
+	edited := mustMarshal(t, []record{{"id": "a", "command": "ls"}, {"id": "b", "command": "pwd"}})
+	if err := os.WriteFile(localFile, edited, 0644); err != nil {
+		t.Fatalf("write edited snippets.json: %v", err)
+	}
+	if err := Push(cfg, []string{localFile}); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+
+	// pull into a second, independent local file and confirm it sees "b"
+	otherDir := t.TempDir()
+	otherFile := filepath.Join(otherDir, "snippets.json")
+	if err := os.WriteFile(otherFile, initial, 0644); err != nil {
+		t.Fatalf("write other snippets.json: %v", err)
+	}
+	if err := Pull(cfg, []string{otherFile}); err != nil {
+		t.Fatalf("Pull (other): %v", err)
+	}
+	data, err = os.ReadFile(otherFile)
+	if err != nil {
+		t.Fatalf("read otherFile after pull: %v", err)
+	}
+	byID := recordsByID(t, data)
+	if _, ok := byID["b"]; !ok {
+		t.Errorf("expected pushed snippet %q to be visible after pull, got %v", "b", byID)
+	}
+}
@@ -0,0 +1,71 @@
+// Package sync pushes and pulls a corgi snippets file to/from a remote,
+// Git-backed store so a snippet library can be shared across machines.
+package sync
+
+import "errors"
+
+// Backend identifies which kind of remote a Config points at.
+type Backend string
+
+const (
+	BackendGit    Backend = "git"
+	BackendGist   Backend = "gist"
+	BackendGitlab Backend = "gitlab"
+	BackendS3     Backend = "s3"
+)
+
+// AuthMethod identifies how to authenticate against the remote.
+type AuthMethod string
+
+const (
+	AuthSSHKey  AuthMethod = "ssh-key"
+	AuthSSHPass AuthMethod = "ssh-pass"
+	AuthToken   AuthMethod = "token"
+)
+
+// Config holds the settings needed to sync a snippets file to/from a remote.
+// It lives at Config.Sync in the main corgi config.
+type Config struct {
+	Backend    Backend    `json:"backend" toml:"backend" yaml:"backend"`
+	URL        string     `json:"url" toml:"url" yaml:"url"`
+	Branch     string     `json:"branch,omitempty" toml:"branch,omitempty" yaml:"branch,omitempty"`
+	AuthMethod AuthMethod `json:"auth_method,omitempty" toml:"auth_method,omitempty" yaml:"auth_method,omitempty"`
+	KeyPath    string     `json:"key_path,omitempty" toml:"key_path,omitempty" yaml:"key_path,omitempty"`
+	Passphrase string     `json:"passphrase,omitempty" toml:"passphrase,omitempty" yaml:"passphrase,omitempty"`
+	AutoSync   bool       `json:"auto_sync,omitempty" toml:"auto_sync,omitempty" yaml:"auto_sync,omitempty"`
+}
+
+var ErrUnsupportedBackend = errors.New("sync: unsupported backend")
+
+// ErrNothingToSync is returned by Pull/Push when localFiles is empty, e.g. a
+// config with neither SnippetsFile nor SnippetsDirs set.
+var ErrNothingToSync = errors.New("sync: no snippets file or directory configured to sync")
+
+// Pull fetches the remote copy of each of localFiles and three-way merges it
+// into the local file, by snippet ID, so local edits made since the last
+// sync survive.
+func Pull(cfg *Config, localFiles []string) error {
+	if len(localFiles) == 0 {
+		return ErrNothingToSync
+	}
+	switch cfg.Backend {
+	case BackendGit, "":
+		return gitPull(cfg, localFiles)
+	default:
+		return ErrUnsupportedBackend
+	}
+}
+
+// Push merges each of localFiles into the remote's copy and pushes it back,
+// so other machines pulling afterwards see this one's edits.
+func Push(cfg *Config, localFiles []string) error {
+	if len(localFiles) == 0 {
+		return ErrNothingToSync
+	}
+	switch cfg.Backend {
+	case BackendGit, "":
+		return gitPush(cfg, localFiles)
+	default:
+		return ErrUnsupportedBackend
+	}
+}